@@ -0,0 +1,83 @@
+package rdb
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/hibiken/asynq/internal/base"
+)
+
+// Dequeue polls qnames, in the order given, for a task to process, blocking
+// for up to timeout. On success it moves the task onto the in-progress
+// queue and returns it; on timeout it returns ErrNoProcessableTask.
+//
+// Strict priority falls directly out of Redis's own BRPOP semantics: given
+// multiple keys, BRPOP checks them left-to-right and pops from the first
+// one that's non-empty. So a processor that always calls
+//
+//	rdb.Dequeue(timeout, "critical", "default", "low")
+//
+// will drain "critical" before ever touching "default", with no extra
+// bookkeeping needed here. For weighted-fair consumption across queues,
+// callers can instead pass an ordering produced by ShuffleQueuesByWeight on
+// each call.
+func (r *RDB) Dequeue(timeout time.Duration, qnames ...string) (*base.TaskMessage, error) {
+	if len(qnames) == 0 {
+		qnames = []string{base.DefaultQueueName}
+	}
+	keys := make([]string, len(qnames))
+	for i, qname := range qnames {
+		keys[i] = base.QueueKey(qname)
+	}
+	res, err := r.client.BRPop(timeout, keys...).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrNoProcessableTask
+		}
+		return nil, err
+	}
+	data := res[1]
+	var msg base.TaskMessage
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		return nil, err
+	}
+	if err := r.client.LPush(base.InProgressQueue, data).Err(); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// ShuffleQueuesByWeight returns qnames reordered so that, on average over
+// many calls, a queue is picked first proportionally to its weight.
+// Queues absent from weights default to a weight of 1. Combined with
+// Dequeue (which always drains whichever queue sorts first), calling this
+// once per dequeue and feeding the result to Dequeue approximates weighted
+// fair scheduling across queues, as opposed to the fixed ordering used for
+// strict priority.
+func ShuffleQueuesByWeight(qnames []string, weights map[string]int) []string {
+	type weighted struct {
+		name string
+		key  float64
+	}
+	items := make([]weighted, len(qnames))
+	for i, qname := range qnames {
+		w := weights[qname]
+		if w <= 0 {
+			w = 1
+		}
+		// Efraimidis-Spirakis weighted random sampling: keying each item by
+		// an Exp(1) draw scaled by 1/weight and sorting ascending yields an
+		// ordering whose first element is weight-proportionally likely to
+		// be any given queue.
+		items[i] = weighted{name: qname, key: rand.ExpFloat64() / float64(w)}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].key < items[j].key })
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.name
+	}
+	return out
+}
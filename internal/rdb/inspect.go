@@ -3,6 +3,8 @@ package rdb
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 
 // Stats represents a state of queues at a certain time.
 type Stats struct {
+	Queue      string
 	Enqueued   int
 	InProgress int
 	Scheduled  int
@@ -72,10 +75,17 @@ type DeadTask struct {
 	Score        int64
 }
 
-// CurrentStats returns a current state of the queues.
+// CurrentStats returns a current state of the default queue.
 func (r *RDB) CurrentStats() (*Stats, error) {
+	return r.CurrentStatsByQueue(base.DefaultQueueName)
+}
+
+// CurrentStatsByQueue returns a current state of the given queue. Only
+// Enqueued is specific to qname; InProgress, Scheduled, Retry and Dead are
+// counted across all queues.
+func (r *RDB) CurrentStatsByQueue(qname string) (*Stats, error) {
 	pipe := r.client.Pipeline()
-	qlen := pipe.LLen(base.DefaultQueue)
+	qlen := pipe.LLen(base.QueueKey(qname))
 	plen := pipe.LLen(base.InProgressQueue)
 	slen := pipe.ZCard(base.ScheduledQueue)
 	rlen := pipe.ZCard(base.RetryQueue)
@@ -85,6 +95,7 @@ func (r *RDB) CurrentStats() (*Stats, error) {
 		return nil, err
 	}
 	return &Stats{
+		Queue:      qname,
 		Enqueued:   int(qlen.Val()),
 		InProgress: int(plen.Val()),
 		Scheduled:  int(slen.Val()),
@@ -94,6 +105,191 @@ func (r *RDB) CurrentStats() (*Stats, error) {
 	}, nil
 }
 
+// ListQueues returns the names of all queues that have ever been used.
+//
+// See Dequeue and ShuffleQueuesByWeight for the consumption side of named
+// queues: strict priority and weighted fair scheduling across queues are
+// both driven from here via the order in which queue keys are passed to
+// BRPOP, rather than from a separate processor component.
+func (r *RDB) ListQueues() ([]string, error) {
+	return r.client.SMembers(base.AllQueues).Result()
+}
+
+// TypeStats represents the number of tasks of a given type that have been
+// processed and that have failed since the counters were last reset.
+type TypeStats struct {
+	Processed int64
+	Failed    int64
+}
+
+// RecordStatsSnapshot stores s in the stats ring for the day, keyed by hour,
+// minute and queue name. Each day's ring is kept in its own hash so that it can be
+// expired as a whole once it falls out of the retention window, giving a
+// fixed-size ring buffer over time. See StatsSampler for the component
+// that calls this on an interval; HistoricalStats has nothing to return
+// unless a sampler (or some other caller) is actually recording snapshots.
+func (r *RDB) RecordStatsSnapshot(s *Stats) error {
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("could not marshal stats snapshot: %v", err)
+	}
+	field := s.Timestamp.Format("15:04") + ":" + s.Queue
+	key := base.StatsKey(s.Timestamp)
+	pipe := r.client.Pipeline()
+	pipe.HSet(key, field, string(bytes))
+	pipe.Expire(key, base.StatsRetention)
+	_, err = pipe.Exec()
+	return err
+}
+
+// HistoricalStats returns the recorded stats snapshots between from and to,
+// inclusive, ordered from oldest to newest. Minutes for which no snapshot
+// was recorded are omitted rather than zero-filled.
+func (r *RDB) HistoricalStats(from, to time.Time) ([]*Stats, error) {
+	var stats []*Stats
+	// Step by calendar date rather than adding 24h to the raw `from` time:
+	// adding 24h to a `from` that's late in the day can jump past `to`'s
+	// own day entirely (e.g. from=Jan1 23:50, to=Jan2 00:10), silently
+	// skipping the bucket that holds every snapshot actually in range.
+	start := truncateToDay(from)
+	end := truncateToDay(to)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		data, err := r.client.HGetAll(base.StatsKey(day)).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range data {
+			var s Stats
+			if err := json.Unmarshal([]byte(v), &s); err != nil {
+				continue // bad data, ignore and continue
+			}
+			if s.Timestamp.Before(from) || s.Timestamp.After(to) {
+				continue
+			}
+			stats = append(stats, &s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Timestamp.Before(stats[j].Timestamp)
+	})
+	return stats, nil
+}
+
+// truncateToDay returns t with its time-of-day components zeroed out, in
+// t's own location, so day-stepping loops land on calendar-day boundaries.
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// IncrementProcessedByType increments the processed counter for the given
+// task type. Nothing in this package calls it, so StatsByType reports only
+// the processed counts that callers of this function have recorded.
+func (r *RDB) IncrementProcessedByType(taskType string) error {
+	return r.client.HIncrBy(base.ProcessedByTypeKey, taskType, 1).Err()
+}
+
+// IncrementFailedByType increments the failed counter for the given task
+// type. Nothing in this package calls it, so StatsByType reports only the
+// failed counts that callers of this function have recorded.
+func (r *RDB) IncrementFailedByType(taskType string) error {
+	return r.client.HIncrBy(base.FailedByTypeKey, taskType, 1).Err()
+}
+
+// StatsByType returns the processed and failed counts for every task type
+// seen so far.
+func (r *RDB) StatsByType() (map[string]TypeStats, error) {
+	pipe := r.client.Pipeline()
+	processedCmd := pipe.HGetAll(base.ProcessedByTypeKey)
+	failedCmd := pipe.HGetAll(base.FailedByTypeKey)
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+	out := make(map[string]TypeStats)
+	for taskType, v := range processedCmd.Val() {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue // bad data, ignore and continue
+		}
+		ts := out[taskType]
+		ts.Processed = n
+		out[taskType] = ts
+	}
+	for taskType, v := range failedCmd.Val() {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue // bad data, ignore and continue
+		}
+		ts := out[taskType]
+		ts.Failed = n
+		out[taskType] = ts
+	}
+	return out, nil
+}
+
+// validatePageArgs rejects the zero-value/negative page and pageSize
+// combinations that would otherwise turn stop := start+pageSize-1 into -1,
+// i.e. "give me the whole list" via LRANGE/ZRANGE key 0 -1 — exactly the
+// unbounded read pagination exists to avoid.
+func validatePageArgs(page, pageSize int) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("asynq: pageSize must be a positive integer, got %d", pageSize)
+	}
+	if page < 0 {
+		return fmt.Errorf("asynq: page must not be negative, got %d", page)
+	}
+	return nil
+}
+
+// pageBounds returns the slice bounds [start, end) of the page-th window of
+// size pageSize over a collection of n items, clamped to n.
+func pageBounds(n, page, pageSize int) (start, end int) {
+	start = page * pageSize
+	if start > n {
+		start = n
+	}
+	end = start + pageSize
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+// listPaged returns the elements of a Redis list within [page*pageSize,
+// page*pageSize+pageSize) along with the total length of the list.
+func (r *RDB) listPaged(key string, page, pageSize int) (data []string, total int64, err error) {
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	pipe := r.client.Pipeline()
+	start := int64(page * pageSize)
+	stop := start + int64(pageSize) - 1
+	dataCmd := pipe.LRange(key, start, stop)
+	totalCmd := pipe.LLen(key)
+	if _, err := pipe.Exec(); err != nil {
+		return nil, 0, err
+	}
+	return dataCmd.Val(), totalCmd.Val(), nil
+}
+
+// zrangePaged returns the elements of a Redis sorted set within
+// [page*pageSize, page*pageSize+pageSize) along with the total cardinality
+// of the set.
+func (r *RDB) zrangePaged(key string, page, pageSize int) (data []redis.Z, total int64, err error) {
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	pipe := r.client.Pipeline()
+	start := int64(page * pageSize)
+	stop := start + int64(pageSize) - 1
+	dataCmd := pipe.ZRangeWithScores(key, start, stop)
+	totalCmd := pipe.ZCard(key)
+	if _, err := pipe.Exec(); err != nil {
+		return nil, 0, err
+	}
+	return dataCmd.Val(), totalCmd.Val(), nil
+}
+
 // RedisInfo returns a map of redis info.
 func (r *RDB) RedisInfo() (map[string]string, error) {
 	res, err := r.client.Info().Result()
@@ -111,9 +307,10 @@ func (r *RDB) RedisInfo() (map[string]string, error) {
 	return info, nil
 }
 
-// ListEnqueued returns all enqueued tasks that are ready to be processed.
-func (r *RDB) ListEnqueued() ([]*EnqueuedTask, error) {
-	data, err := r.client.LRange(base.DefaultQueue, 0, -1).Result()
+// ListEnqueued returns all enqueued tasks that are ready to be processed
+// in the given queue.
+func (r *RDB) ListEnqueued(qname string) ([]*EnqueuedTask, error) {
+	data, err := r.client.LRange(base.QueueKey(qname), 0, -1).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +331,58 @@ func (r *RDB) ListEnqueued() ([]*EnqueuedTask, error) {
 	return tasks, nil
 }
 
+// ListEnqueuedPaged returns a page of enqueued tasks that are ready to be
+// processed in the given queue, along with the total number of enqueued
+// tasks in that queue. If taskType is non-empty, only tasks of that type
+// are returned and the reported total is the count of matching tasks, not
+// the whole queue; since Redis has no secondary index on task type, this
+// path scans the full queue rather than using a bounded LRANGE.
+func (r *RDB) ListEnqueuedPaged(qname string, page, pageSize int, taskType string) ([]*EnqueuedTask, int64, error) {
+	if taskType == "" {
+		data, total, err := r.listPaged(base.QueueKey(qname), page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var tasks []*EnqueuedTask
+		for _, s := range data {
+			var msg base.TaskMessage
+			if err := json.Unmarshal([]byte(s), &msg); err != nil {
+				return nil, 0, err
+			}
+			tasks = append(tasks, &EnqueuedTask{
+				ID:      msg.ID,
+				Type:    msg.Type,
+				Payload: msg.Payload,
+			})
+		}
+		return tasks, total, nil
+	}
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	data, err := r.client.LRange(base.QueueKey(qname), 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	var matched []*EnqueuedTask
+	for _, s := range data {
+		var msg base.TaskMessage
+		if err := json.Unmarshal([]byte(s), &msg); err != nil {
+			return nil, 0, err
+		}
+		if msg.Type != taskType {
+			continue
+		}
+		matched = append(matched, &EnqueuedTask{
+			ID:      msg.ID,
+			Type:    msg.Type,
+			Payload: msg.Payload,
+		})
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	return matched[start:end], int64(len(matched)), nil
+}
+
 // ListInProgress returns all tasks that are currently being processed.
 func (r *RDB) ListInProgress() ([]*InProgressTask, error) {
 	data, err := r.client.LRange(base.InProgressQueue, 0, -1).Result()
@@ -156,6 +405,57 @@ func (r *RDB) ListInProgress() ([]*InProgressTask, error) {
 	return tasks, nil
 }
 
+// ListInProgressPaged returns a page of tasks that are currently being
+// processed, along with the total number of in-progress tasks. If taskType
+// is non-empty, only tasks of that type are returned and the reported
+// total is the count of matching tasks; see ListEnqueuedPaged for why this
+// path scans the full list instead of using a bounded LRANGE.
+func (r *RDB) ListInProgressPaged(page, pageSize int, taskType string) ([]*InProgressTask, int64, error) {
+	if taskType == "" {
+		data, total, err := r.listPaged(base.InProgressQueue, page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var tasks []*InProgressTask
+		for _, s := range data {
+			var msg base.TaskMessage
+			if err := json.Unmarshal([]byte(s), &msg); err != nil {
+				continue // bad data, ignore and continue
+			}
+			tasks = append(tasks, &InProgressTask{
+				ID:      msg.ID,
+				Type:    msg.Type,
+				Payload: msg.Payload,
+			})
+		}
+		return tasks, total, nil
+	}
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	data, err := r.client.LRange(base.InProgressQueue, 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	var matched []*InProgressTask
+	for _, s := range data {
+		var msg base.TaskMessage
+		if err := json.Unmarshal([]byte(s), &msg); err != nil {
+			continue // bad data, ignore and continue
+		}
+		if msg.Type != taskType {
+			continue
+		}
+		matched = append(matched, &InProgressTask{
+			ID:      msg.ID,
+			Type:    msg.Type,
+			Payload: msg.Payload,
+		})
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	return matched[start:end], int64(len(matched)), nil
+}
+
 // ListScheduled returns all tasks that are scheduled to be processed
 // in the future.
 func (r *RDB) ListScheduled() ([]*ScheduledTask, error) {
@@ -186,6 +486,69 @@ func (r *RDB) ListScheduled() ([]*ScheduledTask, error) {
 	return tasks, nil
 }
 
+// ListScheduledPaged returns a page of tasks that are scheduled to be
+// processed in the future, along with the total number of scheduled tasks.
+// If taskType is non-empty, only tasks of that type are returned and the
+// reported total is the count of matching tasks; see ListEnqueuedPaged for
+// why this path scans the full set instead of using a bounded ZRANGE.
+func (r *RDB) ListScheduledPaged(page, pageSize int, taskType string) ([]*ScheduledTask, int64, error) {
+	if taskType == "" {
+		data, total, err := r.zrangePaged(base.ScheduledQueue, page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var tasks []*ScheduledTask
+		for _, z := range data {
+			s, ok := z.Member.(string)
+			if !ok {
+				continue // bad data, ignore and continue
+			}
+			var msg base.TaskMessage
+			if err := json.Unmarshal([]byte(s), &msg); err != nil {
+				continue // bad data, ignore and continue
+			}
+			tasks = append(tasks, &ScheduledTask{
+				ID:        msg.ID,
+				Type:      msg.Type,
+				Payload:   msg.Payload,
+				ProcessAt: time.Unix(int64(z.Score), 0),
+				Score:     int64(z.Score),
+			})
+		}
+		return tasks, total, nil
+	}
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	data, err := r.client.ZRangeWithScores(base.ScheduledQueue, 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	var matched []*ScheduledTask
+	for _, z := range data {
+		s, ok := z.Member.(string)
+		if !ok {
+			continue // bad data, ignore and continue
+		}
+		var msg base.TaskMessage
+		if err := json.Unmarshal([]byte(s), &msg); err != nil {
+			continue // bad data, ignore and continue
+		}
+		if msg.Type != taskType {
+			continue
+		}
+		matched = append(matched, &ScheduledTask{
+			ID:        msg.ID,
+			Type:      msg.Type,
+			Payload:   msg.Payload,
+			ProcessAt: time.Unix(int64(z.Score), 0),
+			Score:     int64(z.Score),
+		})
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	return matched[start:end], int64(len(matched)), nil
+}
+
 // ListRetry returns all tasks that have failed before and willl be retried
 // in the future.
 func (r *RDB) ListRetry() ([]*RetryTask, error) {
@@ -219,6 +582,75 @@ func (r *RDB) ListRetry() ([]*RetryTask, error) {
 	return tasks, nil
 }
 
+// ListRetryPaged returns a page of tasks that are in the retry queue,
+// along with the total number of tasks awaiting retry. If taskType is
+// non-empty, only tasks of that type are returned and the reported total
+// is the count of matching tasks; see ListEnqueuedPaged for why this path
+// scans the full set instead of using a bounded ZRANGE.
+func (r *RDB) ListRetryPaged(page, pageSize int, taskType string) ([]*RetryTask, int64, error) {
+	if taskType == "" {
+		data, total, err := r.zrangePaged(base.RetryQueue, page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var tasks []*RetryTask
+		for _, z := range data {
+			s, ok := z.Member.(string)
+			if !ok {
+				continue // bad data, ignore and continue
+			}
+			var msg base.TaskMessage
+			if err := json.Unmarshal([]byte(s), &msg); err != nil {
+				continue // bad data, ignore and continue
+			}
+			tasks = append(tasks, &RetryTask{
+				ID:        msg.ID,
+				Type:      msg.Type,
+				Payload:   msg.Payload,
+				ErrorMsg:  msg.ErrorMsg,
+				Retry:     msg.Retry,
+				Retried:   msg.Retried,
+				ProcessAt: time.Unix(int64(z.Score), 0),
+				Score:     int64(z.Score),
+			})
+		}
+		return tasks, total, nil
+	}
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	data, err := r.client.ZRangeWithScores(base.RetryQueue, 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	var matched []*RetryTask
+	for _, z := range data {
+		s, ok := z.Member.(string)
+		if !ok {
+			continue // bad data, ignore and continue
+		}
+		var msg base.TaskMessage
+		if err := json.Unmarshal([]byte(s), &msg); err != nil {
+			continue // bad data, ignore and continue
+		}
+		if msg.Type != taskType {
+			continue
+		}
+		matched = append(matched, &RetryTask{
+			ID:        msg.ID,
+			Type:      msg.Type,
+			Payload:   msg.Payload,
+			ErrorMsg:  msg.ErrorMsg,
+			Retry:     msg.Retry,
+			Retried:   msg.Retried,
+			ProcessAt: time.Unix(int64(z.Score), 0),
+			Score:     int64(z.Score),
+		})
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	return matched[start:end], int64(len(matched)), nil
+}
+
 // ListDead returns all tasks that have exhausted its retry limit.
 func (r *RDB) ListDead() ([]*DeadTask, error) {
 	data, err := r.client.ZRangeWithScores(base.DeadQueue, 0, -1).Result()
@@ -249,6 +681,71 @@ func (r *RDB) ListDead() ([]*DeadTask, error) {
 	return tasks, nil
 }
 
+// ListDeadPaged returns a page of tasks that have exhausted their retry
+// limit, along with the total number of dead tasks. If taskType is
+// non-empty, only tasks of that type are returned and the reported total
+// is the count of matching tasks; see ListEnqueuedPaged for why this path
+// scans the full set instead of using a bounded ZRANGE.
+func (r *RDB) ListDeadPaged(page, pageSize int, taskType string) ([]*DeadTask, int64, error) {
+	if taskType == "" {
+		data, total, err := r.zrangePaged(base.DeadQueue, page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var tasks []*DeadTask
+		for _, z := range data {
+			s, ok := z.Member.(string)
+			if !ok {
+				continue // bad data, ignore and continue
+			}
+			var msg base.TaskMessage
+			if err := json.Unmarshal([]byte(s), &msg); err != nil {
+				continue // bad data, ignore and continue
+			}
+			tasks = append(tasks, &DeadTask{
+				ID:           msg.ID,
+				Type:         msg.Type,
+				Payload:      msg.Payload,
+				ErrorMsg:     msg.ErrorMsg,
+				LastFailedAt: time.Unix(int64(z.Score), 0),
+				Score:        int64(z.Score),
+			})
+		}
+		return tasks, total, nil
+	}
+	if err := validatePageArgs(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+	data, err := r.client.ZRangeWithScores(base.DeadQueue, 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	var matched []*DeadTask
+	for _, z := range data {
+		s, ok := z.Member.(string)
+		if !ok {
+			continue // bad data, ignore and continue
+		}
+		var msg base.TaskMessage
+		if err := json.Unmarshal([]byte(s), &msg); err != nil {
+			continue // bad data, ignore and continue
+		}
+		if msg.Type != taskType {
+			continue
+		}
+		matched = append(matched, &DeadTask{
+			ID:           msg.ID,
+			Type:         msg.Type,
+			Payload:      msg.Payload,
+			ErrorMsg:     msg.ErrorMsg,
+			LastFailedAt: time.Unix(int64(z.Score), 0),
+			Score:        int64(z.Score),
+		})
+	}
+	start, end := pageBounds(len(matched), page, pageSize)
+	return matched[start:end], int64(len(matched)), nil
+}
+
 // EnqueueDeadTask finds a task that matches the given id and score from dead queue
 // and enqueues it for processing. If a task that matches the id and score
 // does not exist, it returns ErrTaskNotFound.
@@ -291,6 +788,34 @@ func (r *RDB) EnqueueScheduledTask(id xid.ID, score int64) error {
 	return nil
 }
 
+// TaskKey uniquely identifies a task sitting in one of the sorted-set
+// queues (scheduled, retry, dead) by the id and score it was stored under.
+type TaskKey struct {
+	ID    xid.ID
+	Score int64
+}
+
+// EnqueueDeadTasks finds all tasks that match the given keys in the dead
+// queue and enqueues them for processing. It returns the number of tasks
+// successfully enqueued.
+func (r *RDB) EnqueueDeadTasks(keys []TaskKey) (int64, error) {
+	return r.batchRemoveAndEnqueue(base.DeadQueue, keys)
+}
+
+// EnqueueRetryTasks finds all tasks that match the given keys in the retry
+// queue and enqueues them for processing. It returns the number of tasks
+// successfully enqueued.
+func (r *RDB) EnqueueRetryTasks(keys []TaskKey) (int64, error) {
+	return r.batchRemoveAndEnqueue(base.RetryQueue, keys)
+}
+
+// EnqueueScheduledTasks finds all tasks that match the given keys in the
+// scheduled queue and enqueues them for processing. It returns the number
+// of tasks successfully enqueued.
+func (r *RDB) EnqueueScheduledTasks(keys []TaskKey) (int64, error) {
+	return r.batchRemoveAndEnqueue(base.ScheduledQueue, keys)
+}
+
 // EnqueueAllScheduledTasks enqueues all tasks from scheduled queue
 // and returns the number of tasks enqueued.
 func (r *RDB) EnqueueAllScheduledTasks() (int64, error) {
@@ -309,6 +834,75 @@ func (r *RDB) EnqueueAllDeadTasks() (int64, error) {
 	return r.removeAndEnqueueAll(base.DeadQueue)
 }
 
+// EnqueueUnique enqueues the given task message onto the queue named in the
+// message, unless uniqueKey is already held by another task. The guard is
+// set with the given ttl, keyed to msg.ID, so that it self-clears even if
+// the task's worker never calls ClearUniqueKey (e.g. it crashed). It
+// returns ErrDuplicateTask if uniqueKey is already held.
+//
+// This package only provides the storage primitive: releasing the guard on
+// successful completion, as opposed to waiting out the ttl, requires a
+// worker/processor to call ClearUniqueKey when a task finishes. No such
+// caller exists in this tree, so as things stand every unique guard lives
+// for its full ttl regardless of how quickly the task actually completes.
+func (r *RDB) EnqueueUnique(msg *base.TaskMessage, uniqueKey string, ttl time.Duration) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("could not marshal task message: %v", err)
+	}
+	// Redis rejects "EX 0"; round sub-second ttls up to one second rather
+	// than let a plausible caller input (e.g. 500ms) turn into a hard error.
+	ttlSeconds := int(ttl.Seconds())
+	if ttlSeconds < 1 {
+		ttlSeconds = 1
+	}
+	script := redis.NewScript(`
+	if redis.call("SET", KEYS[1], ARGV[1], "NX", "EX", ARGV[2]) then
+		redis.call("LPUSH", KEYS[2], ARGV[3])
+		return 1
+	end
+	return 0
+	`)
+	qname := msg.Queue
+	if qname == "" {
+		qname = base.DefaultQueueName
+	}
+	res, err := script.Run(r.client, []string{uniqueKey, base.QueueKey(qname)},
+		msg.ID.String(), ttlSeconds, string(bytes)).Result()
+	if err != nil {
+		return err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return fmt.Errorf("could not cast %v to int64", res)
+	}
+	if n == 0 {
+		return ErrDuplicateTask
+	}
+	return nil
+}
+
+// ClearUniqueKey releases the uniqueness guard held under uniqueKey, but
+// only if it's still holding the token for id. Workers are meant to call
+// this once a task enqueued via EnqueueUnique has completed successfully,
+// so the key can be reused right away instead of waiting out its ttl — but
+// wiring that call into a worker/processor is out of scope here, since no
+// such component exists in this package. The ownership check keeps a late
+// call (its guard already expired and reacquired by a different task in
+// the meantime) from deleting someone else's live guard.
+func (r *RDB) ClearUniqueKey(uniqueKey string, id xid.ID) error {
+	script := redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+	`)
+	return script.Run(r.client, []string{uniqueKey}, id.String()).Err()
+}
+
+// removeAndEnqueue moves the task matching id and score from zset back onto
+// the queue named in the task message, so it can be picked up by a worker
+// again regardless of which named queue it originally belonged to.
 func (r *RDB) removeAndEnqueue(zset, id string, score float64) (int64, error) {
 	script := redis.NewScript(`
 	local msgs = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[1], ARGV[1])
@@ -316,13 +910,66 @@ func (r *RDB) removeAndEnqueue(zset, id string, score float64) (int64, error) {
 		local decoded = cjson.decode(msg)
 		if decoded["ID"] == ARGV[2] then
 			redis.call("ZREM", KEYS[1], msg)
-			redis.call("LPUSH", KEYS[2], msg)
+			local q = decoded["Queue"]
+			if q == nil or q == "" then
+				q = ARGV[4]
+			end
+			local qkey = ARGV[3] .. q
+			redis.call("LPUSH", qkey, msg)
 			return 1
 		end
 	end
 	return 0
 	`)
-	res, err := script.Run(r.client, []string{zset, base.DefaultQueue}, score, id).Result()
+	res, err := script.Run(r.client, []string{zset}, score, id, base.QueuePrefix, base.DefaultQueueName).Result()
+	if err != nil {
+		return 0, err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return 0, fmt.Errorf("could not cast %v to int64", res)
+	}
+	return n, nil
+}
+
+// batchRemoveAndEnqueue moves every task matching one of keys from zset back
+// onto the queue named in its task message, atomically in a single script
+// invocation. It returns the number of tasks successfully moved; keys that
+// don't match any task in zset are silently skipped.
+func (r *RDB) batchRemoveAndEnqueue(zset string, keys []TaskKey) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	script := redis.NewScript(`
+	local qprefix = ARGV[1]
+	local defaultq = ARGV[2]
+	local count = 0
+	for i = 3, #ARGV, 2 do
+		local msgs = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[i], ARGV[i])
+		for _, msg in ipairs(msgs) do
+			local decoded = cjson.decode(msg)
+			if decoded["ID"] == ARGV[i+1] then
+				redis.call("ZREM", KEYS[1], msg)
+				local q = decoded["Queue"]
+				if q == nil or q == "" then
+					q = defaultq
+				end
+				local qkey = qprefix .. q
+				redis.call("LPUSH", qkey, msg)
+				count = count + 1
+				break
+			end
+		end
+	end
+	return count
+	`)
+	argv := make([]interface{}, 2, 2+2*len(keys))
+	argv[0] = base.QueuePrefix
+	argv[1] = base.DefaultQueueName
+	for _, key := range keys {
+		argv = append(argv, key.Score, key.ID.String())
+	}
+	res, err := script.Run(r.client, []string{zset}, argv...).Result()
 	if err != nil {
 		return 0, err
 	}
@@ -338,11 +985,17 @@ func (r *RDB) removeAndEnqueueAll(zset string) (int64, error) {
 	local msgs = redis.call("ZRANGE", KEYS[1], 0, -1)
 	for _, msg in ipairs(msgs) do
 		redis.call("ZREM", KEYS[1], msg)
-		redis.call("LPUSH", KEYS[2], msg)
+		local decoded = cjson.decode(msg)
+		local q = decoded["Queue"]
+		if q == nil or q == "" then
+			q = ARGV[2]
+		end
+		local qkey = ARGV[1] .. q
+		redis.call("LPUSH", qkey, msg)
 	end
 	return table.getn(msgs)
 	`)
-	res, err := script.Run(r.client, []string{zset, base.DefaultQueue}).Result()
+	res, err := script.Run(r.client, []string{zset}, base.QueuePrefix, base.DefaultQueueName).Result()
 	if err != nil {
 		return 0, err
 	}
@@ -374,6 +1027,65 @@ func (r *RDB) DeleteScheduledTask(id xid.ID, score int64) error {
 	return r.deleteTask(base.ScheduledQueue, id.String(), float64(score))
 }
 
+// DeleteDeadTasks finds all tasks that match the given keys in the dead
+// queue and deletes them. It returns the number of tasks successfully
+// deleted.
+func (r *RDB) DeleteDeadTasks(keys []TaskKey) (int64, error) {
+	return r.batchDeleteTask(base.DeadQueue, keys)
+}
+
+// DeleteRetryTasks finds all tasks that match the given keys in the retry
+// queue and deletes them. It returns the number of tasks successfully
+// deleted.
+func (r *RDB) DeleteRetryTasks(keys []TaskKey) (int64, error) {
+	return r.batchDeleteTask(base.RetryQueue, keys)
+}
+
+// DeleteScheduledTasks finds all tasks that match the given keys in the
+// scheduled queue and deletes them. It returns the number of tasks
+// successfully deleted.
+func (r *RDB) DeleteScheduledTasks(keys []TaskKey) (int64, error) {
+	return r.batchDeleteTask(base.ScheduledQueue, keys)
+}
+
+// batchDeleteTask deletes every task matching one of keys from zset,
+// atomically in a single script invocation. It returns the number of tasks
+// successfully deleted; keys that don't match any task in zset are
+// silently skipped.
+func (r *RDB) batchDeleteTask(zset string, keys []TaskKey) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	script := redis.NewScript(`
+	local count = 0
+	for i = 1, #ARGV, 2 do
+		local msgs = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[i], ARGV[i])
+		for _, msg in ipairs(msgs) do
+			local decoded = cjson.decode(msg)
+			if decoded["ID"] == ARGV[i+1] then
+				redis.call("ZREM", KEYS[1], msg)
+				count = count + 1
+				break
+			end
+		end
+	end
+	return count
+	`)
+	argv := make([]interface{}, 0, 2*len(keys))
+	for _, key := range keys {
+		argv = append(argv, key.Score, key.ID.String())
+	}
+	res, err := script.Run(r.client, []string{zset}, argv...).Result()
+	if err != nil {
+		return 0, err
+	}
+	n, ok := res.(int64)
+	if !ok {
+		return 0, fmt.Errorf("could not cast %v to int64", res)
+	}
+	return n, nil
+}
+
 func (r *RDB) deleteTask(zset, id string, score float64) error {
 	script := redis.NewScript(`
 	local msgs = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[1], ARGV[1])
@@ -0,0 +1,401 @@
+package rdb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+	"github.com/hibiken/asynq/internal/base"
+	"github.com/rs/xid"
+)
+
+func newTestRDB(t *testing.T) *RDB {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("could not start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return &RDB{client: client}
+}
+
+func TestClearUniqueKeyOnlyReleasesOwnToken(t *testing.T) {
+	r := newTestRDB(t)
+	uniqueKey := "asynq:unique:mytype:mypayload"
+	msgA := &base.TaskMessage{ID: xid.New(), Type: "mytype"}
+	msgB := &base.TaskMessage{ID: xid.New(), Type: "mytype"}
+
+	if err := r.EnqueueUnique(msgA, uniqueKey, time.Minute); err != nil {
+		t.Fatalf("first EnqueueUnique failed: %v", err)
+	}
+	// Simulate A's guard expiring and B legitimately reacquiring the key.
+	if err := r.client.Del(uniqueKey).Err(); err != nil {
+		t.Fatalf("could not simulate ttl expiry: %v", err)
+	}
+	if err := r.EnqueueUnique(msgB, uniqueKey, time.Minute); err != nil {
+		t.Fatalf("second EnqueueUnique failed: %v", err)
+	}
+
+	// A's late cleanup must not delete B's still-live guard.
+	if err := r.ClearUniqueKey(uniqueKey, msgA.ID); err != nil {
+		t.Fatalf("ClearUniqueKey(A) failed: %v", err)
+	}
+	if _, err := r.client.Get(uniqueKey).Result(); err != nil {
+		t.Fatalf("B's guard was deleted by A's stale ClearUniqueKey call: %v", err)
+	}
+
+	// B's own cleanup releases it.
+	if err := r.ClearUniqueKey(uniqueKey, msgB.ID); err != nil {
+		t.Fatalf("ClearUniqueKey(B) failed: %v", err)
+	}
+	if _, err := r.client.Get(uniqueKey).Result(); err != redis.Nil {
+		t.Fatalf("want guard cleared, got err=%v", err)
+	}
+}
+
+func TestListEnqueuedPagedRejectsBadArgs(t *testing.T) {
+	r := newTestRDB(t)
+	if err := enqueueMsg(r, "default", &base.TaskMessage{ID: xid.New(), Type: "t"}); err != nil {
+		t.Fatalf("could not seed queue: %v", err)
+	}
+
+	if _, _, err := r.ListEnqueuedPaged("default", 0, 0, ""); err == nil {
+		t.Fatal("want error for pageSize=0, got nil (this used to silently return the whole queue)")
+	}
+	if _, _, err := r.ListEnqueuedPaged("default", -1, 10, ""); err == nil {
+		t.Fatal("want error for negative page, got nil")
+	}
+}
+
+func TestListEnqueuedPagedFilteredTotalReflectsMatches(t *testing.T) {
+	r := newTestRDB(t)
+	for i := 0; i < 3; i++ {
+		if err := enqueueMsg(r, "default", &base.TaskMessage{ID: xid.New(), Type: "common"}); err != nil {
+			t.Fatalf("could not seed queue: %v", err)
+		}
+	}
+	if err := enqueueMsg(r, "default", &base.TaskMessage{ID: xid.New(), Type: "rare"}); err != nil {
+		t.Fatalf("could not seed queue: %v", err)
+	}
+
+	tasks, total, err := r.ListEnqueuedPaged("default", 0, 10, "rare")
+	if err != nil {
+		t.Fatalf("ListEnqueuedPaged failed: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("total = %d, want 1 (count of matching tasks, not whole queue)", total)
+	}
+	if len(tasks) != 1 || tasks[0].Type != "rare" {
+		t.Errorf("tasks = %+v, want a single rare task", tasks)
+	}
+}
+
+func enqueueMsg(r *RDB, qname string, msg *base.TaskMessage) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return r.client.LPush(base.QueueKey(qname), string(bytes)).Err()
+}
+
+func TestHistoricalStatsSpanningMidnight(t *testing.T) {
+	r := newTestRDB(t)
+	loc := time.UTC
+	day1 := time.Date(2026, 7, 26, 23, 50, 0, 0, loc)
+	day2 := time.Date(2026, 7, 27, 0, 5, 0, 0, loc)
+
+	if err := r.RecordStatsSnapshot(&Stats{Queue: "default", Timestamp: day1}); err != nil {
+		t.Fatalf("RecordStatsSnapshot(day1) failed: %v", err)
+	}
+	if err := r.RecordStatsSnapshot(&Stats{Queue: "default", Timestamp: day2}); err != nil {
+		t.Fatalf("RecordStatsSnapshot(day2) failed: %v", err)
+	}
+
+	from := time.Date(2026, 7, 26, 23, 45, 0, 0, loc)
+	to := time.Date(2026, 7, 27, 0, 10, 0, 0, loc)
+	stats, err := r.HistoricalStats(from, to)
+	if err != nil {
+		t.Fatalf("HistoricalStats failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d snapshots, want 2 (day2's bucket must not be skipped)", len(stats))
+	}
+}
+
+func TestStatsSamplerRecordsSnapshots(t *testing.T) {
+	r := newTestRDB(t)
+	if err := enqueueMsg(r, "default", &base.TaskMessage{ID: xid.New(), Type: "t"}); err != nil {
+		t.Fatalf("could not seed queue: %v", err)
+	}
+
+	sampler := NewStatsSampler(r, 10*time.Millisecond)
+	done := make(chan struct{})
+	go sampler.Start(done)
+	defer close(done)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		stats, err := r.HistoricalStats(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("HistoricalStats failed: %v", err)
+		}
+		if len(stats) > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("StatsSampler never recorded a snapshot within the deadline")
+}
+
+func TestDequeuePrefersHigherPriorityQueue(t *testing.T) {
+	r := newTestRDB(t)
+	if err := enqueueMsg(r, "default", &base.TaskMessage{ID: xid.New(), Type: "t"}); err != nil {
+		t.Fatalf("could not seed default queue: %v", err)
+	}
+	critical := &base.TaskMessage{ID: xid.New(), Type: "t"}
+	if err := enqueueMsg(r, "critical", critical); err != nil {
+		t.Fatalf("could not seed critical queue: %v", err)
+	}
+
+	msg, err := r.Dequeue(time.Second, "critical", "default")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if msg.ID != critical.ID {
+		t.Errorf("Dequeue returned task %v, want the critical-queue task %v", msg.ID, critical.ID)
+	}
+
+	n, err := r.client.LLen(base.InProgressQueue).Result()
+	if err != nil {
+		t.Fatalf("LLen(InProgress) failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("in-progress queue has %d members, want 1", n)
+	}
+}
+
+func TestShuffleQueuesByWeightKeepsAllNames(t *testing.T) {
+	qnames := []string{"critical", "default", "low"}
+	weights := map[string]int{"critical": 6, "default": 3, "low": 1}
+
+	shuffled := ShuffleQueuesByWeight(qnames, weights)
+	if len(shuffled) != len(qnames) {
+		t.Fatalf("got %d names, want %d", len(shuffled), len(qnames))
+	}
+	seen := make(map[string]bool)
+	for _, name := range shuffled {
+		seen[name] = true
+	}
+	for _, name := range qnames {
+		if !seen[name] {
+			t.Errorf("shuffled result is missing %q", name)
+		}
+	}
+}
+
+func TestRemoveAndEnqueueTreatsEmptyQueueAsDefault(t *testing.T) {
+	r := newTestRDB(t)
+	// Queue left as the zero value (""), as opposed to explicitly "default".
+	msg := &base.TaskMessage{ID: xid.New(), Type: "t"}
+	if err := zaddDead(r, msg, 1); err != nil {
+		t.Fatalf("could not seed dead queue: %v", err)
+	}
+
+	if err := r.EnqueueDeadTask(msg.ID, 1); err != nil {
+		t.Fatalf("EnqueueDeadTask failed: %v", err)
+	}
+
+	n, err := r.client.LLen(base.QueueKey(base.DefaultQueueName)).Result()
+	if err != nil {
+		t.Fatalf("LLen(default) failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("default queue has %d members, want 1 (task with empty Queue must land on default, not \"asynq:queues:\")", n)
+	}
+}
+
+func TestBatchRemoveAndEnqueueMovesMatchingTasks(t *testing.T) {
+	r := newTestRDB(t)
+	keep := &base.TaskMessage{ID: xid.New(), Type: "t", Queue: "critical"}
+	move1 := &base.TaskMessage{ID: xid.New(), Type: "t", Queue: "critical"}
+	move2 := &base.TaskMessage{ID: xid.New(), Type: "t", Queue: "default"}
+	for i, msg := range []*base.TaskMessage{keep, move1, move2} {
+		if err := zaddDead(r, msg, int64(i)); err != nil {
+			t.Fatalf("could not seed dead queue: %v", err)
+		}
+	}
+
+	n, err := r.EnqueueDeadTasks([]TaskKey{{ID: move1.ID, Score: 1}, {ID: move2.ID, Score: 2}})
+	if err != nil {
+		t.Fatalf("EnqueueDeadTasks failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("n = %d, want 2", n)
+	}
+
+	card, err := r.client.ZCard(base.DeadQueue).Result()
+	if err != nil {
+		t.Fatalf("ZCard failed: %v", err)
+	}
+	if card != 1 {
+		t.Errorf("dead queue has %d members, want 1 (only the untouched task)", card)
+	}
+	critLen, err := r.client.LLen(base.QueueKey("critical")).Result()
+	if err != nil {
+		t.Fatalf("LLen(critical) failed: %v", err)
+	}
+	if critLen != 1 {
+		t.Errorf("critical queue has %d members, want 1", critLen)
+	}
+	defLen, err := r.client.LLen(base.QueueKey("default")).Result()
+	if err != nil {
+		t.Fatalf("LLen(default) failed: %v", err)
+	}
+	if defLen != 1 {
+		t.Errorf("default queue has %d members, want 1", defLen)
+	}
+}
+
+func TestBatchDeleteTaskDeletesOnlyMatchingKeys(t *testing.T) {
+	r := newTestRDB(t)
+	keep := &base.TaskMessage{ID: xid.New(), Type: "t"}
+	gone := &base.TaskMessage{ID: xid.New(), Type: "t"}
+	if err := zaddDead(r, keep, 1); err != nil {
+		t.Fatalf("could not seed dead queue: %v", err)
+	}
+	if err := zaddDead(r, gone, 2); err != nil {
+		t.Fatalf("could not seed dead queue: %v", err)
+	}
+
+	n, err := r.DeleteDeadTasks([]TaskKey{{ID: gone.ID, Score: 2}})
+	if err != nil {
+		t.Fatalf("DeleteDeadTasks failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("n = %d, want 1", n)
+	}
+	card, err := r.client.ZCard(base.DeadQueue).Result()
+	if err != nil {
+		t.Fatalf("ZCard failed: %v", err)
+	}
+	if card != 1 {
+		t.Errorf("dead queue has %d members, want 1 (only the untouched task)", card)
+	}
+}
+
+func zaddDead(r *RDB, msg *base.TaskMessage, score int64) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return r.client.ZAdd(base.DeadQueue, &redis.Z{Score: float64(score), Member: string(bytes)}).Err()
+}
+
+func zaddScheduled(r *RDB, msg *base.TaskMessage, score int64) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return r.client.ZAdd(base.ScheduledQueue, &redis.Z{Score: float64(score), Member: string(bytes)}).Err()
+}
+
+func TestListScheduledPagedFilteredTotalReflectsMatches(t *testing.T) {
+	r := newTestRDB(t)
+	for i := 0; i < 3; i++ {
+		msg := &base.TaskMessage{ID: xid.New(), Type: "common"}
+		if err := zaddScheduled(r, msg, int64(i)); err != nil {
+			t.Fatalf("could not seed scheduled queue: %v", err)
+		}
+	}
+	rare := &base.TaskMessage{ID: xid.New(), Type: "rare"}
+	if err := zaddScheduled(r, rare, 10); err != nil {
+		t.Fatalf("could not seed scheduled queue: %v", err)
+	}
+
+	tasks, total, err := r.ListScheduledPaged(0, 10, "rare")
+	if err != nil {
+		t.Fatalf("ListScheduledPaged failed: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("total = %d, want 1", total)
+	}
+	if len(tasks) != 1 || tasks[0].ID != rare.ID {
+		t.Errorf("tasks = %+v, want a single rare task", tasks)
+	}
+
+	if _, _, err := r.ListScheduledPaged(0, 0, ""); err == nil {
+		t.Fatal("want error for pageSize=0, got nil")
+	}
+}
+
+func TestEnqueueRetryTasksAndDeleteScheduledTasks(t *testing.T) {
+	r := newTestRDB(t)
+	retryA := &base.TaskMessage{ID: xid.New(), Type: "t", Queue: "default"}
+	retryB := &base.TaskMessage{ID: xid.New(), Type: "t", Queue: "default"}
+	if err := r.client.ZAdd(base.RetryQueue,
+		&redis.Z{Score: 1, Member: mustMarshal(t, retryA)},
+		&redis.Z{Score: 2, Member: mustMarshal(t, retryB)},
+	).Err(); err != nil {
+		t.Fatalf("could not seed retry queue: %v", err)
+	}
+
+	n, err := r.EnqueueRetryTasks([]TaskKey{{ID: retryA.ID, Score: 1}, {ID: retryB.ID, Score: 2}})
+	if err != nil {
+		t.Fatalf("EnqueueRetryTasks failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("n = %d, want 2", n)
+	}
+	qlen, err := r.client.LLen(base.QueueKey("default")).Result()
+	if err != nil {
+		t.Fatalf("LLen(default) failed: %v", err)
+	}
+	if qlen != 2 {
+		t.Errorf("default queue has %d members, want 2", qlen)
+	}
+
+	schedA := &base.TaskMessage{ID: xid.New(), Type: "t"}
+	schedB := &base.TaskMessage{ID: xid.New(), Type: "t"}
+	if err := zaddScheduled(r, schedA, 1); err != nil {
+		t.Fatalf("could not seed scheduled queue: %v", err)
+	}
+	if err := zaddScheduled(r, schedB, 2); err != nil {
+		t.Fatalf("could not seed scheduled queue: %v", err)
+	}
+
+	n, err = r.DeleteScheduledTasks([]TaskKey{{ID: schedA.ID, Score: 1}})
+	if err != nil {
+		t.Fatalf("DeleteScheduledTasks failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("n = %d, want 1", n)
+	}
+	card, err := r.client.ZCard(base.ScheduledQueue).Result()
+	if err != nil {
+		t.Fatalf("ZCard(scheduled) failed: %v", err)
+	}
+	if card != 1 {
+		t.Errorf("scheduled queue has %d members, want 1 (only the untouched task)", card)
+	}
+}
+
+func mustMarshal(t *testing.T, msg *base.TaskMessage) string {
+	t.Helper()
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("could not marshal task message: %v", err)
+	}
+	return string(bytes)
+}
+
+func TestEnqueueUniqueSubSecondTTL(t *testing.T) {
+	r := newTestRDB(t)
+	msg := &base.TaskMessage{ID: xid.New(), Type: "mytype"}
+
+	if err := r.EnqueueUnique(msg, "asynq:unique:mytype:mypayload", 500*time.Millisecond); err != nil {
+		t.Fatalf("EnqueueUnique with sub-second ttl should round up, not error: %v", err)
+	}
+}
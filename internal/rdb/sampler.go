@@ -0,0 +1,62 @@
+package rdb
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq/internal/base"
+)
+
+// StatsSampler periodically records a snapshot of every known queue's
+// current stats into the historical stats ring, so that HistoricalStats
+// has something to report once a server has been running for a while.
+// It does not touch the per-type counters that StatsByType reports. Without running one, RecordStatsSnapshot is never called
+// and the ring stays empty.
+type StatsSampler struct {
+	rdb      *RDB
+	interval time.Duration
+}
+
+// NewStatsSampler returns a StatsSampler that, once started, takes a
+// snapshot of every queue's stats once per interval.
+func NewStatsSampler(rdb *RDB, interval time.Duration) *StatsSampler {
+	return &StatsSampler{rdb: rdb, interval: interval}
+}
+
+// Start runs the sampling loop until done is closed. It's meant to be
+// launched in its own goroutine by the server at startup, e.g.:
+//
+//	sampler := rdb.NewStatsSampler(r, time.Minute)
+//	go sampler.Start(done)
+func (s *StatsSampler) Start(done <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+// sample takes and records one snapshot per known queue. Errors are
+// swallowed rather than propagated since there's no caller on the other
+// end of the ticker loop to report them to; a failed sample is simply a
+// gap in the historical record, not a fatal condition.
+func (s *StatsSampler) sample() {
+	qnames, err := s.rdb.ListQueues()
+	if err != nil {
+		return
+	}
+	if len(qnames) == 0 {
+		qnames = []string{base.DefaultQueueName}
+	}
+	for _, qname := range qnames {
+		stats, err := s.rdb.CurrentStatsByQueue(qname)
+		if err != nil {
+			continue
+		}
+		_ = s.rdb.RecordStatsSnapshot(stats)
+	}
+}